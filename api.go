@@ -8,3 +8,19 @@ import (
 type Exchange interface {
 	SecretKey(ephemeralPrivateKey *ecdsa.PrivateKey, theirPublicKey *ecdsa.PublicKey, theirAgreementInfo []byte) ([]byte, error)
 }
+
+// KeyWrap represents the ECDH-1PU Key Agreement with Key Wrapping contract
+// (ECDH-1PU+A128KW / +A192KW / +A256KW). Unlike Direct Key Agreement, the
+// KDF output here is a key-encryption key used to wrap a randomly generated
+// CEK, and the wrap is authenticated by binding the JWE authentication tag
+// into the KDF fixed info.
+type KeyWrap interface {
+	// WrapKey derives the KEK and uses it to wrap cek, returning the
+	// wrapped CEK and the ephemeral public key to embed in the JWE header.
+	// tag is the JWE authentication tag produced by encrypting the payload
+	// with cek, which binds the wrap to that specific ciphertext.
+	WrapKey(cek, tag []byte) (wrappedCEK []byte, epk *ecdsa.PublicKey, err error)
+	// UnwrapKey reverses WrapKey given the sender ephemeral public key
+	// carried in the JWE header.
+	UnwrapKey(senderEphemeralPublicKey *ecdsa.PublicKey, wrappedCEK, tag []byte) (cek []byte, err error)
+}
@@ -13,13 +13,24 @@ type ecdh1PuExchange struct {
 	algorithmID []byte
 	dkLenBits   uint32
 	partyInfo   []byte
-	h           func() hash.Hash
+	kdf         KDF
 }
 
+// ECDH1PU returns an ECDH-1PU Direct Key Agreement implementation deriving
+// its output with the SP 800-56A one-step concatenation KDF, using h as the
+// underlying hash function. It is a compatibility shim over ECDH1PUWithKDF
+// for callers that don't need a different KDF.
 func ECDH1PU(staticPrivateKey *ecdsa.PrivateKey, h func() hash.Hash, algorithmID []byte, dkLenBits uint32, agreementPartyInfo []byte) Exchange {
+	return ECDH1PUWithKDF(staticPrivateKey, NISTConcatKDF{Hash: h}, algorithmID, dkLenBits, agreementPartyInfo)
+}
+
+// ECDH1PUWithKDF is identical to ECDH1PU but lets the caller plug in an
+// arbitrary KDF (HKDF, KMAC, ...) instead of the default NIST concat KDF,
+// for interop with stacks that expect one of those.
+func ECDH1PUWithKDF(staticPrivateKey *ecdsa.PrivateKey, kdf KDF, algorithmID []byte, dkLenBits uint32, agreementPartyInfo []byte) Exchange {
 	return &ecdh1PuExchange{
 		ourPrivate:  staticPrivateKey,
-		h:           h,
+		kdf:         kdf,
 		algorithmID: algorithmID,
 		dkLenBits:   dkLenBits,
 		partyInfo:   agreementPartyInfo,
@@ -36,8 +47,8 @@ func (exchange *ecdh1PuExchange) SecretKey(ephemeralPrivateKey *ecdsa.PrivateKey
 	if theirPublicKey == nil {
 		return nil, fmt.Errorf("their public key is mandatory")
 	}
-	if exchange.h == nil {
-		return nil, fmt.Errorf("hash function is mandatory")
+	if exchange.kdf == nil {
+		return nil, fmt.Errorf("kdf is mandatory")
 	}
 
 	// Compute sharedSecret
@@ -46,20 +57,68 @@ func (exchange *ecdh1PuExchange) SecretKey(ephemeralPrivateKey *ecdsa.PrivateKey
 		return nil, err
 	}
 
-	// Prepare info: ( AlgorithmID || PartyUInfo || PartyVInfo || KeyLength )
+	// No error
+	return exchange.deriveKey(sharedSecret, theirAgreementInfo)
+}
+
+// -----------------------------------------------------------------
+
+// SecretKeyFromSender derives the same shared secret as SecretKey, but from
+// the recipient's point of view: Z = (Ze || Zs) is reconstructed from our
+// static private key combined with the sender's ephemeral and static public
+// keys. ECDH is symmetric, so this yields the identical bytes the sender
+// computed, letting a recipient re-derive a Direct Key Agreement CEK from
+// the epk/apu/apv carried in a JWE header.
+func (exchange *ecdh1PuExchange) SecretKeyFromSender(senderEphemeralPublicKey, senderStaticPublicKey *ecdsa.PublicKey, theirAgreementInfo []byte) ([]byte, error) {
+	// Check arguments
+	if senderEphemeralPublicKey == nil {
+		return nil, fmt.Errorf("sender ephemeral public key is mandatory")
+	}
+	if senderStaticPublicKey == nil {
+		return nil, fmt.Errorf("sender static public key is mandatory")
+	}
+	if exchange.ourPrivate == nil {
+		return nil, fmt.Errorf("unable to process with nil private key")
+	}
+	if exchange.kdf == nil {
+		return nil, fmt.Errorf("kdf is mandatory")
+	}
+
+	// Compute Ze - ECDH(ourPrivate, senderEphemeralPublic)
+	Ze, err := computeECDH(exchange.ourPrivate, senderEphemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute ephemeral shared secret: %v", err)
+	}
+
+	// Compute Zs - ECDH(ourPrivate, senderStaticPublic)
+	Zs, err := computeECDH(exchange.ourPrivate, senderStaticPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute static shared secret: %v", err)
+	}
+
+	// Z = (Ze || Zs)
+	sharedSecret := append(Ze, Zs...)
+
+	// No error
+	return exchange.deriveKey(sharedSecret, theirAgreementInfo)
+}
+
+// -----------------------------------------------------------------
+
+// deriveKey runs the configured KDF over sharedSecret, using fixed info
+// built from ( AlgorithmID || PartyUInfo || PartyVInfo || KeyLength ).
+func (exchange *ecdh1PuExchange) deriveKey(sharedSecret, theirAgreementInfo []byte) ([]byte, error) {
 	fixedInfo := []byte{}
 	fixedInfo = append(fixedInfo, lengthPrefixedArray(exchange.algorithmID)...)
 	fixedInfo = append(fixedInfo, lengthPrefixedArray(exchange.partyInfo)...)
 	fixedInfo = append(fixedInfo, lengthPrefixedArray(theirAgreementInfo)...)
 	fixedInfo = append(fixedInfo, uint32ToBytes(exchange.dkLenBits)...)
 
-	// Compute KDF
-	dk, err := nistKdf(exchange.h(), sharedSecret, fixedInfo, exchange.dkLenBits>>3)
+	dk, err := exchange.kdf.Derive(sharedSecret, fixedInfo, int(exchange.dkLenBits>>3))
 	if err != nil {
 		return nil, fmt.Errorf("unable to apply kdf: %v", err)
 	}
 
-	// No error
 	return dk, nil
 }
 
@@ -78,18 +137,43 @@ func (exchange *ecdh1PuExchange) computeSharedSecret(ourEphemeralPrivateKey *ecd
 	}
 
 	// Compute Ze - ECDH(localPrivateEphemeral, remotePublic)
-	Ze, _ := ourEphemeralPrivateKey.Curve.ScalarMult(theirPublicKey.X, theirPublicKey.Y, ourEphemeralPrivateKey.D.Bytes())
+	Ze, err := computeECDH(ourEphemeralPrivateKey, theirPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute ephemeral shared secret: %v", err)
+	}
 
 	// Compute Zs - ECDH(localPrivate, remotePublic)
-	Zs, _ := exchange.ourPrivate.Curve.ScalarMult(theirPublicKey.X, theirPublicKey.Y, exchange.ourPrivate.D.Bytes())
+	Zs, err := computeECDH(exchange.ourPrivate, theirPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute static shared secret: %v", err)
+	}
 
 	// Z = (Ze || Zs)
-	Z := append(Ze.Bytes(), Zs.Bytes()...)
+	Z := append(Ze, Zs...)
 
 	// No error
 	return Z, nil
 }
 
+// computeECDH computes ECDH(priv, pub) via crypto/ecdh rather than
+// priv.Curve.ScalarMult(...).Bytes(). ScalarMult followed by big.Int.Bytes()
+// strips leading zero bytes from the shared X coordinate, so concatenating
+// Ze and Zs that way can silently produce a Z of variable length; crypto/ecdh
+// always returns the fixed-length encoding and runs in constant time.
+func computeECDH(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) ([]byte, error) {
+	ecdhPriv, err := priv.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert private key to crypto/ecdh: %v", err)
+	}
+
+	ecdhPub, err := pub.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert public key to crypto/ecdh: %v", err)
+	}
+
+	return ecdhPriv.ECDH(ecdhPub)
+}
+
 // ----------------------------------------------------------------
 
 func lengthPrefixedArray(value []byte) []byte {
@@ -0,0 +1,162 @@
+package exchange
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"hash"
+
+	"zntr.io/exchange/internal/cipher"
+)
+
+type ecdh1PuKeyWrapExchange struct {
+	ourPrivate  *ecdsa.PrivateKey
+	ephemeral   *ecdsa.PrivateKey
+	theirPublic *ecdsa.PublicKey
+	algorithmID []byte
+	dkLenBits   uint32
+	partyUInfo  []byte
+	partyVInfo  []byte
+	kdf         KDF
+}
+
+// ECDH1PUKeyWrap returns an ECDH-1PU Key Agreement with Key Wrapping
+// implementation (ECDH-1PU+A128KW / +A192KW / +A256KW, selected by
+// algorithmID and dkLenBits), deriving the KEK with the SP 800-56A one-step
+// concatenation KDF. The ephemeral key pair is generated by the caller
+// rather than by WrapKey, so it can be embedded in the JWE header before
+// the authentication tag -- and therefore the KEK -- is known. It is a
+// compatibility shim over ECDH1PUKeyWrapWithKDF for callers that don't need
+// a different KDF.
+func ECDH1PUKeyWrap(ourStaticPrivateKey, ephemeralPrivateKey *ecdsa.PrivateKey, theirStaticPublicKey *ecdsa.PublicKey, h func() hash.Hash, algorithmID []byte, dkLenBits uint32, partyUInfo, partyVInfo []byte) KeyWrap {
+	return ECDH1PUKeyWrapWithKDF(ourStaticPrivateKey, ephemeralPrivateKey, theirStaticPublicKey, NISTConcatKDF{Hash: h}, algorithmID, dkLenBits, partyUInfo, partyVInfo)
+}
+
+// ECDH1PUKeyWrapWithKDF is identical to ECDH1PUKeyWrap but lets the caller
+// plug in an arbitrary KDF (HKDF, KMAC, ...) to derive the KEK instead of
+// the default NIST concat KDF.
+func ECDH1PUKeyWrapWithKDF(ourStaticPrivateKey, ephemeralPrivateKey *ecdsa.PrivateKey, theirStaticPublicKey *ecdsa.PublicKey, kdf KDF, algorithmID []byte, dkLenBits uint32, partyUInfo, partyVInfo []byte) KeyWrap {
+	return &ecdh1PuKeyWrapExchange{
+		ourPrivate:  ourStaticPrivateKey,
+		ephemeral:   ephemeralPrivateKey,
+		theirPublic: theirStaticPublicKey,
+		kdf:         kdf,
+		algorithmID: algorithmID,
+		dkLenBits:   dkLenBits,
+		partyUInfo:  partyUInfo,
+		partyVInfo:  partyVInfo,
+	}
+}
+
+// -----------------------------------------------------------------
+
+func (kw *ecdh1PuKeyWrapExchange) WrapKey(cek, tag []byte) ([]byte, *ecdsa.PublicKey, error) {
+	if kw.ephemeral == nil {
+		return nil, nil, fmt.Errorf("ephemeral private key is mandatory")
+	}
+
+	kek, err := kw.deriveKEK(tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := cipher.KeyWrap(kek, cek)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to wrap cek: %v", err)
+	}
+
+	return wrapped, &kw.ephemeral.PublicKey, nil
+}
+
+func (kw *ecdh1PuKeyWrapExchange) UnwrapKey(senderEphemeralPublicKey *ecdsa.PublicKey, wrapped, tag []byte) ([]byte, error) {
+	kek, err := kw.deriveKEKFromPeer(senderEphemeralPublicKey, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := cipher.KeyUnwrap(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unwrap cek: %v", err)
+	}
+
+	return cek, nil
+}
+
+// -----------------------------------------------------------------
+
+// deriveKEK computes the sender-side Z = (Ze || Zs) using our ephemeral
+// private key and static private key against the recipient static public
+// key, then runs the KDF with the tag bound into fixed info.
+func (kw *ecdh1PuKeyWrapExchange) deriveKEK(tag []byte) ([]byte, error) {
+	if kw.ourPrivate == nil {
+		return nil, fmt.Errorf("unable to process with nil private key")
+	}
+	if kw.theirPublic == nil {
+		return nil, fmt.Errorf("their static public key is mandatory")
+	}
+	if kw.kdf == nil {
+		return nil, fmt.Errorf("kdf is mandatory")
+	}
+
+	Ze, err := computeECDH(kw.ephemeral, kw.theirPublic)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute ephemeral shared secret: %v", err)
+	}
+	Zs, err := computeECDH(kw.ourPrivate, kw.theirPublic)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute static shared secret: %v", err)
+	}
+	Z := append(Ze, Zs...)
+
+	return kw.deriveFromZ(Z, tag)
+}
+
+// deriveKEKFromPeer recomputes the same Z from the recipient's point of
+// view: ECDH is symmetric, so combining our static private key with the
+// sender's ephemeral public key (for Ze) and the sender's static public key
+// (for Zs) yields the identical shared secret the sender derived.
+func (kw *ecdh1PuKeyWrapExchange) deriveKEKFromPeer(senderEphemeralPublicKey *ecdsa.PublicKey, tag []byte) ([]byte, error) {
+	if senderEphemeralPublicKey == nil {
+		return nil, fmt.Errorf("sender ephemeral public key is mandatory")
+	}
+	if kw.ourPrivate == nil {
+		return nil, fmt.Errorf("unable to process with nil private key")
+	}
+	if kw.theirPublic == nil {
+		return nil, fmt.Errorf("their static public key is mandatory")
+	}
+	if kw.kdf == nil {
+		return nil, fmt.Errorf("kdf is mandatory")
+	}
+
+	Ze, err := computeECDH(kw.ourPrivate, senderEphemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute ephemeral shared secret: %v", err)
+	}
+	Zs, err := computeECDH(kw.ourPrivate, kw.theirPublic)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute static shared secret: %v", err)
+	}
+	Z := append(Ze, Zs...)
+
+	return kw.deriveFromZ(Z, tag)
+}
+
+func (kw *ecdh1PuKeyWrapExchange) deriveFromZ(Z, tag []byte) ([]byte, error) {
+	// Prepare info: ( AlgorithmID || PartyUInfo || PartyVInfo || KeyLength || Tag )
+	// The draft's SuppPubInfo is KeyDataLen || cctag, with the tag octets
+	// appended raw -- unlike AlgorithmID/PartyUInfo/PartyVInfo it is NOT
+	// length-prefixed, since doing so would drift from spec-compliant peers.
+	fixedInfo := []byte{}
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(kw.algorithmID)...)
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(kw.partyUInfo)...)
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(kw.partyVInfo)...)
+	fixedInfo = append(fixedInfo, uint32ToBytes(kw.dkLenBits)...)
+	fixedInfo = append(fixedInfo, tag...)
+
+	kek, err := kw.kdf.Derive(Z, fixedInfo, int(kw.dkLenBits>>3))
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply kdf: %v", err)
+	}
+
+	return kek, nil
+}
@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func Test_ecdh1PuKeyWrapExchange_RoundTrip(t *testing.T) {
+	ephemeral := aliceEphemeralJWK.Key.(*ecdsa.PrivateKey)
+	alicePrivate := aliceStaticJWK.Key.(*ecdsa.PrivateKey)
+	bobPrivate := bobStaticJWK.Key.(*ecdsa.PrivateKey)
+	bobPublic := bobStaticJWK.Public().Key.(*ecdsa.PublicKey)
+	alicePublic := aliceStaticJWK.Public().Key.(*ecdsa.PublicKey)
+
+	cek := []byte("0123456789ABCDEF")
+	tag := []byte("the-jwe-authentication-tag")
+
+	sender := ECDH1PUKeyWrap(alicePrivate, ephemeral, bobPublic, sha256.New, []byte("A128KW"), 128, []byte("Alice"), []byte("Bob"))
+	wrapped, epk, err := sender.WrapKey(cek, tag)
+	if err != nil {
+		t.Fatalf("WrapKey() error = %v", err)
+	}
+	if epk == nil {
+		t.Fatal("WrapKey() returned nil epk")
+	}
+
+	recipient := ECDH1PUKeyWrap(bobPrivate, nil, alicePublic, sha256.New, []byte("A128KW"), 128, []byte("Alice"), []byte("Bob"))
+	got, err := recipient.UnwrapKey(epk, wrapped, tag)
+	if err != nil {
+		t.Fatalf("UnwrapKey() error = %v", err)
+	}
+	if !bytes.Equal(got, cek) {
+		t.Errorf("UnwrapKey() = %x, want %x", got, cek)
+	}
+}
+
+func Test_ecdh1PuKeyWrapExchange_TamperedTagFailsUnwrap(t *testing.T) {
+	ephemeral := aliceEphemeralJWK.Key.(*ecdsa.PrivateKey)
+	alicePrivate := aliceStaticJWK.Key.(*ecdsa.PrivateKey)
+	bobPrivate := bobStaticJWK.Key.(*ecdsa.PrivateKey)
+	bobPublic := bobStaticJWK.Public().Key.(*ecdsa.PublicKey)
+	alicePublic := aliceStaticJWK.Public().Key.(*ecdsa.PublicKey)
+
+	cek := []byte("0123456789ABCDEF")
+	tag := []byte("the-jwe-authentication-tag")
+
+	sender := ECDH1PUKeyWrap(alicePrivate, ephemeral, bobPublic, sha256.New, []byte("A128KW"), 128, []byte("Alice"), []byte("Bob"))
+	wrapped, epk, err := sender.WrapKey(cek, tag)
+	if err != nil {
+		t.Fatalf("WrapKey() error = %v", err)
+	}
+
+	recipient := ECDH1PUKeyWrap(bobPrivate, nil, alicePublic, sha256.New, []byte("A128KW"), 128, []byte("Alice"), []byte("Bob"))
+	if _, err := recipient.UnwrapKey(epk, wrapped, []byte("a-different-tag")); err == nil {
+		t.Error("UnwrapKey() expected error for mismatched tag, got nil")
+	}
+}
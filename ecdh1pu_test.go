@@ -221,3 +221,34 @@ func Test_ecdh1PuExchange_SecretKey(t *testing.T) {
 		})
 	}
 }
+
+// Test_ecdh1PuExchange_SecretKeyFromSender checks that Bob, deriving the
+// shared secret from Alice's ephemeral and static public keys, reconstructs
+// the exact same bits that Alice derived with SecretKey.
+func Test_ecdh1PuExchange_SecretKeyFromSender(t *testing.T) {
+	alicePrivate := aliceStaticJWK.Key.(*ecdsa.PrivateKey)
+	aliceEphemeralPrivate := aliceEphemeralJWK.Key.(*ecdsa.PrivateKey)
+	alicePublic := aliceStaticJWK.Public().Key.(*ecdsa.PublicKey)
+	aliceEphemeralPublic := aliceEphemeralJWK.Public().Key.(*ecdsa.PublicKey)
+	bobPrivate := bobStaticJWK.Key.(*ecdsa.PrivateKey)
+	bobPublic := bobStaticJWK.Public().Key.(*ecdsa.PublicKey)
+
+	alice := ECDH1PU(alicePrivate, sha256.New, []byte("A256GCM"), 256, []byte("Alice"))
+	want, err := alice.SecretKey(aliceEphemeralPrivate, bobPublic, []byte("Bob"))
+	if err != nil {
+		t.Fatalf("alice.SecretKey() error = %v", err)
+	}
+
+	// Bob's partyInfo slot must carry Alice's apu (PartyUInfo), and the
+	// theirAgreementInfo argument his own apv (PartyVInfo), to reproduce
+	// the exact fixed info Alice used.
+	bob := ECDH1PU(bobPrivate, sha256.New, []byte("A256GCM"), 256, []byte("Alice")).(*ecdh1PuExchange)
+	got, err := bob.SecretKeyFromSender(aliceEphemeralPublic, alicePublic, []byte("Bob"))
+	if err != nil {
+		t.Fatalf("bob.SecretKeyFromSender() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bob.SecretKeyFromSender() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package exchange
+
+import (
+	"crypto/ecdh"
+	"fmt"
+	"hash"
+)
+
+// X25519Exchange represents the key exchange protocol contract for
+// crypto/ecdh keys (such as X25519), mirroring Exchange for the NIST curve /
+// *ecdsa.PrivateKey case. crypto/ecdh has no X448 curve, so X448 keys are
+// not supported.
+type X25519Exchange interface {
+	SecretKey(ephemeralPrivateKey *ecdh.PrivateKey, theirPublicKey *ecdh.PublicKey, theirAgreementInfo []byte) ([]byte, error)
+}
+
+type ecdh1PuX25519Exchange struct {
+	ourPrivate  *ecdh.PrivateKey
+	algorithmID []byte
+	dkLenBits   uint32
+	partyInfo   []byte
+	kdf         KDF
+}
+
+// ECDH1PUX25519 returns an ECDH-1PU Direct Key Agreement implementation
+// operating over X25519 keys (X448 is not supported), required for interop with the
+// Aries/DIDComm messaging stack, which uses ECDH-1PU over X25519
+// exclusively. It is a compatibility shim over ECDH1PUX25519WithKDF for
+// callers that don't need a different KDF.
+func ECDH1PUX25519(staticPrivateKey *ecdh.PrivateKey, h func() hash.Hash, algorithmID []byte, dkLenBits uint32, agreementPartyInfo []byte) X25519Exchange {
+	return ECDH1PUX25519WithKDF(staticPrivateKey, NISTConcatKDF{Hash: h}, algorithmID, dkLenBits, agreementPartyInfo)
+}
+
+// ECDH1PUX25519WithKDF is identical to ECDH1PUX25519 but lets the caller
+// plug in an arbitrary KDF (HKDF, KMAC, ...) instead of the default NIST
+// concat KDF.
+func ECDH1PUX25519WithKDF(staticPrivateKey *ecdh.PrivateKey, kdf KDF, algorithmID []byte, dkLenBits uint32, agreementPartyInfo []byte) X25519Exchange {
+	return &ecdh1PuX25519Exchange{
+		ourPrivate:  staticPrivateKey,
+		kdf:         kdf,
+		algorithmID: algorithmID,
+		dkLenBits:   dkLenBits,
+		partyInfo:   agreementPartyInfo,
+	}
+}
+
+// -----------------------------------------------------------------
+
+func (exchange *ecdh1PuX25519Exchange) SecretKey(ephemeralPrivateKey *ecdh.PrivateKey, theirPublicKey *ecdh.PublicKey, theirAgreementInfo []byte) ([]byte, error) {
+	// Check arguments
+	if ephemeralPrivateKey == nil {
+		return nil, fmt.Errorf("ephemeral private key is mandatory")
+	}
+	if theirPublicKey == nil {
+		return nil, fmt.Errorf("their public key is mandatory")
+	}
+	if exchange.kdf == nil {
+		return nil, fmt.Errorf("kdf is mandatory")
+	}
+
+	// Compute sharedSecret
+	sharedSecret, err := exchange.computeSharedSecret(ephemeralPrivateKey, theirPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return exchange.deriveKey(sharedSecret, theirAgreementInfo)
+}
+
+// deriveKey runs the configured KDF over sharedSecret, using fixed info
+// built from ( AlgorithmID || PartyUInfo || PartyVInfo || KeyLength ).
+func (exchange *ecdh1PuX25519Exchange) deriveKey(sharedSecret, theirAgreementInfo []byte) ([]byte, error) {
+	fixedInfo := []byte{}
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(exchange.algorithmID)...)
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(exchange.partyInfo)...)
+	fixedInfo = append(fixedInfo, lengthPrefixedArray(theirAgreementInfo)...)
+	fixedInfo = append(fixedInfo, uint32ToBytes(exchange.dkLenBits)...)
+
+	dk, err := exchange.kdf.Derive(sharedSecret, fixedInfo, int(exchange.dkLenBits>>3))
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply kdf: %v", err)
+	}
+
+	return dk, nil
+}
+
+// -----------------------------------------------------------------
+
+func (exchange *ecdh1PuX25519Exchange) computeSharedSecret(ourEphemeralPrivateKey *ecdh.PrivateKey, theirPublicKey *ecdh.PublicKey) ([]byte, error) {
+	// Check arguments
+	if exchange.ourPrivate == nil {
+		return nil, fmt.Errorf("unable to process with nil private key")
+	}
+	if ourEphemeralPrivateKey == nil {
+		return nil, fmt.Errorf("unable to process with nil ephemeral private key")
+	}
+	if theirPublicKey == nil {
+		return nil, fmt.Errorf("unable to process with remote public key")
+	}
+
+	// Compute Ze - ECDH(localPrivateEphemeral, remotePublic)
+	Ze, err := ourEphemeralPrivateKey.ECDH(theirPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute ephemeral ecdh: %v", err)
+	}
+
+	// Compute Zs - ECDH(localPrivate, remotePublic)
+	Zs, err := exchange.ourPrivate.ECDH(theirPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute static ecdh: %v", err)
+	}
+
+	// Z = (Ze || Zs)
+	Z := append(append([]byte{}, Ze...), Zs...)
+
+	// No error
+	return Z, nil
+}
@@ -0,0 +1,151 @@
+package exchange
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func Test_ecdh1PuX25519Exchange_SecretKey(t *testing.T) {
+	aliceStatic, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate alice static key: %v", err)
+	}
+	aliceEphemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate alice ephemeral key: %v", err)
+	}
+	bobStatic, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate bob static key: %v", err)
+	}
+
+	type fields struct {
+		ourPrivate  *ecdh.PrivateKey
+		algorithmID []byte
+		dkLenBits   uint32
+		partyInfo   []byte
+	}
+	type args struct {
+		ephemeralPrivateKey *ecdh.PrivateKey
+		theirPublicKey      *ecdh.PublicKey
+		theirAgreementInfo  []byte
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name: "fields: missing private key",
+			fields: fields{
+				ourPrivate:  nil,
+				algorithmID: []byte("A256GCM"),
+				dkLenBits:   256,
+				partyInfo:   []byte("Alice"),
+			},
+			args: args{
+				ephemeralPrivateKey: aliceEphemeral,
+				theirAgreementInfo:  []byte("Bob"),
+				theirPublicKey:      bobStatic.PublicKey(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "args: missing ephemeral key",
+			fields: fields{
+				ourPrivate:  aliceStatic,
+				algorithmID: []byte("A256GCM"),
+				dkLenBits:   256,
+				partyInfo:   []byte("Alice"),
+			},
+			args: args{
+				ephemeralPrivateKey: nil,
+				theirAgreementInfo:  []byte("Bob"),
+				theirPublicKey:      bobStatic.PublicKey(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid: ECDH-1PU+SHA256+A256GCM over X25519",
+			fields: fields{
+				ourPrivate:  aliceStatic,
+				algorithmID: []byte("A256GCM"),
+				dkLenBits:   256,
+				partyInfo:   []byte("Alice"),
+			},
+			args: args{
+				ephemeralPrivateKey: aliceEphemeral,
+				theirAgreementInfo:  []byte("Bob"),
+				theirPublicKey:      bobStatic.PublicKey(),
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange := ECDH1PUX25519(tt.fields.ourPrivate, sha256.New, tt.fields.algorithmID, tt.fields.dkLenBits, tt.fields.partyInfo)
+			got, err := exchange.SecretKey(tt.args.ephemeralPrivateKey, tt.args.theirPublicKey, tt.args.theirAgreementInfo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ecdh1PuX25519Exchange.SecretKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && len(got) != int(tt.fields.dkLenBits>>3) {
+				t.Errorf("ecdh1PuX25519Exchange.SecretKey() returned %d bytes, want %d", len(got), tt.fields.dkLenBits>>3)
+			}
+		})
+	}
+}
+
+// Test_ecdh1PuX25519Exchange_BobAgrees checks that Bob, using his static
+// private key against Alice's ephemeral and static public keys, reconstructs
+// the same Ze and Zs terms Alice computes -- i.e. that ECDH is symmetric for
+// X25519 too. It calls crypto/ecdh directly, not ECDH1PUX25519.
+func Test_ecdh1PuX25519Exchange_BobAgrees(t *testing.T) {
+	aliceStatic, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate alice static key: %v", err)
+	}
+	aliceEphemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate alice ephemeral key: %v", err)
+	}
+	bobStatic, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate bob static key: %v", err)
+	}
+
+	aliceZe, err := aliceEphemeral.ECDH(bobStatic.PublicKey())
+	if err != nil {
+		t.Fatalf("unable to compute alice's Ze: %v", err)
+	}
+	bobZe, err := bobStatic.ECDH(aliceEphemeral.PublicKey())
+	if err != nil {
+		t.Fatalf("unable to compute bob's Ze: %v", err)
+	}
+
+	if !bytes.Equal(aliceZe, bobZe) {
+		t.Errorf("Ze mismatch: alice computed %x, bob computed %x", aliceZe, bobZe)
+	}
+
+	aliceStaticPub := aliceStatic.PublicKey()
+	aliceZs, err := aliceStatic.ECDH(bobStatic.PublicKey())
+	if err != nil {
+		t.Fatalf("unable to compute alice's Zs: %v", err)
+	}
+	bobZs, err := bobStatic.ECDH(aliceStaticPub)
+	if err != nil {
+		t.Fatalf("unable to compute bob's Zs: %v", err)
+	}
+
+	if !bytes.Equal(aliceZs, bobZs) {
+		t.Errorf("Zs mismatch: alice computed %x, bob computed %x", aliceZs, bobZs)
+	}
+}
@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+)
+
+// leadingZero{Recipient,Ephemeral}JWK were found by generating random P-256
+// ephemeral keys against a fixed recipient public key until the raw
+// ScalarMult shared X coordinate had a leading zero byte -- the case
+// computeECDH exists to fix, since big.Int.Bytes() silently drops that
+// leading zero and shortens Z by one byte.
+var (
+	leadingZeroRecipientJWK = mustJWK([]byte(`{"kty":"EC", "crv":"P-256", "x":"lvNe55rmy7TMKyoJYwoTotYGOK8RLvFKhP2ZRD9Rd9A", "y":"dYcEHtbrcjqCU-q72TFV9LOhUY9gywO-PUteBRk2BnI", "d":"l-B2dLBwzIxGuWnKfiF2NTgHKZHuAMDiJjdYO06bYOo"}`))
+	leadingZeroEphemeralJWK = mustJWK([]byte(`{"kty":"EC", "crv":"P-256", "x":"sDTPw6wfr2Qs-tdj7YKE0u3M5ozzJwdtmCyyDo8Yt5E", "y":"aNm-xsSdtXu3zNA4BDnunYRxlb3uPqHQIsydMfeH6Zo", "d":"8_N0twnLNBL-i6jje3hs3VmDsHXSf3_whyid5GR8sDs"}`))
+)
+
+func Test_computeECDH_FixedLengthDespiteLeadingZero(t *testing.T) {
+	recipientPublic := leadingZeroRecipientJWK.Public().Key.(*ecdsa.PublicKey)
+	ephemeralPrivate := leadingZeroEphemeralJWK.Key.(*ecdsa.PrivateKey)
+
+	// Sanity check: the raw ScalarMult X coordinate for this pair really
+	// does have a leading zero byte, i.e. this vector exercises the bug.
+	x, _ := elliptic.P256().ScalarMult(recipientPublic.X, recipientPublic.Y, ephemeralPrivate.D.Bytes())
+	if len(x.Bytes()) != 31 {
+		t.Fatalf("test vector no longer reproduces a short X coordinate, got %d bytes", len(x.Bytes()))
+	}
+
+	got, err := computeECDH(ephemeralPrivate, recipientPublic)
+	if err != nil {
+		t.Fatalf("computeECDH() error = %v", err)
+	}
+	if len(got) != 32 {
+		t.Errorf("computeECDH() returned %d bytes, want 32 (fixed-length P-256 encoding)", len(got))
+	}
+}
+
+// Test_ecdh1PuExchange_computeSharedSecret_LeadingZero checks the full
+// computeSharedSecret path (Ze || Zs) still produces a fixed 64-byte Z when
+// the Ze term hits the leading-zero-byte vector above, where the old
+// ScalarMult(...).Bytes() concatenation would have silently produced 63.
+func Test_ecdh1PuExchange_computeSharedSecret_LeadingZero(t *testing.T) {
+	recipientPublic := leadingZeroRecipientJWK.Public().Key.(*ecdsa.PublicKey)
+	ephemeralPrivate := leadingZeroEphemeralJWK.Key.(*ecdsa.PrivateKey)
+
+	// aliceStaticJWK only stands in for our static key here; what matters is
+	// that the Ze term (ephemeralPrivate, recipientPublic) hits the
+	// leading-zero-byte vector.
+	exchange := ECDH1PU(aliceStaticJWK.Key.(*ecdsa.PrivateKey), sha256.New, []byte("A256GCM"), 256, []byte("Alice")).(*ecdh1PuExchange)
+	Z, err := exchange.computeSharedSecret(ephemeralPrivate, recipientPublic)
+	if err != nil {
+		t.Fatalf("computeSharedSecret() error = %v", err)
+	}
+	if len(Z) != 64 {
+		t.Errorf("computeSharedSecret() returned %d bytes, want 64 (32-byte Ze || 32-byte Zs)", len(Z))
+	}
+}
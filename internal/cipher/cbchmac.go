@@ -0,0 +1,174 @@
+package cipher
+
+import (
+	"crypto/aes"
+	stdcipher "crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// aeadCBCHMAC implements the AES-CBC-HMAC-SHA2 AEAD construction described
+// in RFC 7518 section 5.2: the content is encrypted with AES-CBC and then
+// authenticated with HMAC over the additional data, IV, ciphertext and the
+// bit length of the additional data.
+type aeadCBCHMAC struct {
+	macKey  []byte
+	block   stdcipher.Block
+	hash    func() hash.Hash
+	tagSize int
+}
+
+// NewA128CBCHS256 returns the A128CBC-HS256 content encryption AEAD. key
+// must be 32 bytes: the first 16 are the HMAC-SHA256 key, the last 16 are
+// the AES-128 key.
+func NewA128CBCHS256(key []byte) (stdcipher.AEAD, error) {
+	return newAEADCBCHMAC(key, sha256.New, 16, 16)
+}
+
+// NewA192CBCHS384 returns the A192CBC-HS384 content encryption AEAD. key
+// must be 48 bytes: the first 24 are the HMAC-SHA384 key, the last 24 are
+// the AES-192 key.
+func NewA192CBCHS384(key []byte) (stdcipher.AEAD, error) {
+	return newAEADCBCHMAC(key, sha512.New384, 24, 24)
+}
+
+// NewA256CBCHS512 returns the A256CBC-HS512 content encryption AEAD. key
+// must be 64 bytes: the first 32 are the HMAC-SHA512 key, the last 32 are
+// the AES-256 key.
+func NewA256CBCHS512(key []byte) (stdcipher.AEAD, error) {
+	return newAEADCBCHMAC(key, sha512.New, 32, 32)
+}
+
+func newAEADCBCHMAC(key []byte, h func() hash.Hash, macKeyLen, encKeyLen int) (stdcipher.AEAD, error) {
+	if len(key) != macKeyLen+encKeyLen {
+		return nil, fmt.Errorf("invalid key length: expected %d bytes, got %d", macKeyLen+encKeyLen, len(key))
+	}
+
+	// Build the AES block cipher once here, where an error can be reported
+	// normally, rather than in Seal/Open: Seal's signature (crypto/cipher.AEAD)
+	// has no error return, which would otherwise force it to panic on a
+	// failure Open could return as an error -- an asymmetry that can't fire
+	// today (encKeyLen is always a valid AES key length) but shouldn't exist.
+	block, err := aes.NewCipher(key[macKeyLen:])
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize aes cipher: %v", err)
+	}
+
+	return &aeadCBCHMAC{
+		macKey:  key[:macKeyLen],
+		block:   block,
+		hash:    h,
+		tagSize: encKeyLen,
+	}, nil
+}
+
+func (a *aeadCBCHMAC) NonceSize() int { return aes.BlockSize }
+
+func (a *aeadCBCHMAC) Overhead() int { return aes.BlockSize + a.tagSize }
+
+func (a *aeadCBCHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	stdcipher.NewCBCEncrypter(a.block, nonce).CryptBlocks(ciphertext, padded)
+
+	tag := a.tag(additionalData, nonce, ciphertext)
+
+	ret, out := sliceForAppend(dst, len(ciphertext)+len(tag))
+	copy(out, ciphertext)
+	copy(out[len(ciphertext):], tag)
+
+	return ret
+}
+
+func (a *aeadCBCHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < a.tagSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	rawCiphertext := ciphertext[:len(ciphertext)-a.tagSize]
+	tag := ciphertext[len(ciphertext)-a.tagSize:]
+
+	expected := a.tag(additionalData, nonce, rawCiphertext)
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return nil, fmt.Errorf("message authentication failed")
+	}
+
+	if len(rawCiphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a multiple of the block size")
+	}
+
+	padded := make([]byte, len(rawCiphertext))
+	stdcipher.NewCBCDecrypter(a.block, nonce).CryptBlocks(padded, rawCiphertext)
+
+	// pkcs7Unpad trusts data[len(data)-1] as the pad length without checking
+	// the padding bytes are uniform. That's only safe because the MAC above
+	// has already been verified over this exact ciphertext -- never unpad
+	// before the tag check, or padding-oracle-style behavior differences
+	// become exploitable.
+	plaintext, err := pkcs7Unpad(padded)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext))
+	copy(out, plaintext)
+
+	return ret, nil
+}
+
+// tag computes HMAC(macKey, AAD || IV || ciphertext || AL), truncated to
+// tagSize bytes, where AL is the bit length of AAD as a 64-bit big-endian
+// integer.
+func (a *aeadCBCHMAC) tag(aad, iv, ciphertext []byte) []byte {
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(aad))*8)
+
+	mac := hmac.New(a.hash, a.macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al)
+
+	return mac.Sum(nil)[:a.tagSize]
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data)+padLen)
+	copy(padded, data)
+	for i := len(data); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+
+	return padded
+}
+
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty plaintext")
+	}
+
+	padLen := int(data[len(data)-1])
+	if padLen == 0 || padLen > len(data) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+
+	return data[:len(data)-padLen], nil
+}
+
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+
+	return
+}
@@ -0,0 +1,59 @@
+package cipher
+
+import (
+	"bytes"
+	stdcipher "crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func TestAEADCBCHMAC_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		newAEAD func([]byte) (stdcipher.AEAD, error)
+		keyLen  int
+	}{
+		{name: "A128CBC-HS256", newAEAD: NewA128CBCHS256, keyLen: 32},
+		{name: "A192CBC-HS384", newAEAD: NewA192CBCHS384, keyLen: 48},
+		{name: "A256CBC-HS512", newAEAD: NewA256CBCHS512, keyLen: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x24}, tt.keyLen)
+
+			aead, err := tt.newAEAD(key)
+			if err != nil {
+				t.Fatalf("New...() error = %v", err)
+			}
+
+			nonce := make([]byte, aead.NonceSize())
+			if _, err := rand.Read(nonce); err != nil {
+				t.Fatalf("unable to generate nonce: %v", err)
+			}
+
+			plaintext := []byte("the quick brown fox jumps over the lazy dog")
+			aad := []byte("protected-header")
+
+			sealed := aead.Seal(nil, nonce, plaintext, aad)
+			got, err := aead.Open(nil, nonce, sealed, aad)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("Open() = %q, want %q", got, plaintext)
+			}
+
+			// Tampering with the AAD must invalidate the tag.
+			if _, err := aead.Open(nil, nonce, sealed, []byte("tampered")); err == nil {
+				t.Error("Open() expected authentication failure for tampered aad, got nil error")
+			}
+		})
+	}
+}
+
+func TestNewAEADCBCHMAC_InvalidKeyLength(t *testing.T) {
+	if _, err := NewA128CBCHS256(make([]byte, 10)); err == nil {
+		t.Error("NewA128CBCHS256() expected error for short key, got nil")
+	}
+}
@@ -0,0 +1,108 @@
+// Package cipher provides the low-level symmetric primitives (AES key
+// wrapping and JOSE AEAD content ciphers) used internally to implement
+// ECDH-1PU Key Agreement with Key Wrapping.
+package cipher
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"fmt"
+)
+
+// defaultIV is the fixed initial value defined by RFC 3394 section 2.2.3.1.
+var defaultIV = [8]byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// KeyWrap wraps cek with kek as described in RFC 3394. cek must be a
+// multiple of 8 bytes long and at least 16 bytes (two 64-bit blocks).
+func KeyWrap(kek, cek []byte) ([]byte, error) {
+	if len(cek) < 16 || len(cek)%8 != 0 {
+		return nil, fmt.Errorf("cek length must be a multiple of 8 bytes and at least 16 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize aes cipher: %v", err)
+	}
+
+	n := len(cek) / 8
+	r := make([][8]byte, n)
+	for i := range r {
+		copy(r[i][:], cek[i*8:(i+1)*8])
+	}
+
+	a := defaultIV
+	var buf [16]byte
+	for j := 0; j <= 5; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf[:8], a[:])
+			copy(buf[8:], r[i-1][:])
+			block.Encrypt(buf[:], buf[:])
+
+			copy(a[:], buf[:8])
+			xorCounter(a[:], uint64(n*j+i))
+			copy(r[i-1][:], buf[8:])
+		}
+	}
+
+	out := make([]byte, 0, 8+len(cek))
+	out = append(out, a[:]...)
+	for _, block := range r {
+		out = append(out, block[:]...)
+	}
+
+	return out, nil
+}
+
+// KeyUnwrap reverses KeyWrap, returning an error if the integrity check
+// fails.
+func KeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, fmt.Errorf("wrapped key length is invalid")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize aes cipher: %v", err)
+	}
+
+	n := len(wrapped)/8 - 1
+	r := make([][8]byte, n)
+	for i := range r {
+		copy(r[i][:], wrapped[(i+1)*8:(i+2)*8])
+	}
+
+	var a [8]byte
+	copy(a[:], wrapped[:8])
+
+	var buf [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			copy(buf[:8], a[:])
+			xorCounter(buf[:8], uint64(n*j+i))
+			copy(buf[8:], r[i-1][:])
+			block.Decrypt(buf[:], buf[:])
+
+			copy(a[:], buf[:8])
+			copy(r[i-1][:], buf[8:])
+		}
+	}
+
+	if a != defaultIV {
+		return nil, fmt.Errorf("key unwrap integrity check failed")
+	}
+
+	out := make([]byte, 0, len(wrapped)-8)
+	for _, block := range r {
+		out = append(out, block[:]...)
+	}
+
+	return out, nil
+}
+
+func xorCounter(msb []byte, t uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], t)
+	for i := range msb {
+		msb[i] ^= buf[i]
+	}
+}
@@ -0,0 +1,76 @@
+package cipher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex literal %q: %v", s, err)
+	}
+
+	return b
+}
+
+// Test vector from RFC 3394 section 4.1 (Wrap 128 bits of Key Data with a
+// 128-bit KEK).
+func TestKeyWrap_RFC3394Vector(t *testing.T) {
+	kek := mustHex(t, "000102030405060708090A0B0C0D0E0F")
+	cek := mustHex(t, "00112233445566778899AABBCCDDEEFF")
+	want := mustHex(t, "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+
+	got, err := KeyWrap(kek, cek)
+	if err != nil {
+		t.Fatalf("KeyWrap() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("KeyWrap() = %x, want %x", got, want)
+	}
+
+	unwrapped, err := KeyUnwrap(kek, got)
+	if err != nil {
+		t.Fatalf("KeyUnwrap() error = %v", err)
+	}
+	if !bytes.Equal(unwrapped, cek) {
+		t.Errorf("KeyUnwrap() = %x, want %x", unwrapped, cek)
+	}
+}
+
+func TestKeyWrap_RoundTrip(t *testing.T) {
+	for _, kekLen := range []int{16, 24, 32} {
+		kek := bytes.Repeat([]byte{0x42}, kekLen)
+		cek := bytes.Repeat([]byte{0x07}, 32)
+
+		wrapped, err := KeyWrap(kek, cek)
+		if err != nil {
+			t.Fatalf("KeyWrap() error = %v", err)
+		}
+
+		unwrapped, err := KeyUnwrap(kek, wrapped)
+		if err != nil {
+			t.Fatalf("KeyUnwrap() error = %v", err)
+		}
+		if !bytes.Equal(unwrapped, cek) {
+			t.Errorf("KeyUnwrap() = %x, want %x", unwrapped, cek)
+		}
+	}
+}
+
+func TestKeyUnwrap_TamperedIntegrity(t *testing.T) {
+	kek := bytes.Repeat([]byte{0x42}, 16)
+	cek := bytes.Repeat([]byte{0x07}, 16)
+
+	wrapped, err := KeyWrap(kek, cek)
+	if err != nil {
+		t.Fatalf("KeyWrap() error = %v", err)
+	}
+	wrapped[0] ^= 0xFF
+
+	if _, err := KeyUnwrap(kek, wrapped); err == nil {
+		t.Error("KeyUnwrap() expected integrity check failure, got nil error")
+	}
+}
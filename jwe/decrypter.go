@@ -0,0 +1,150 @@
+package jwe
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"crypto/ecdsa"
+
+	"zntr.io/exchange"
+)
+
+// senderSecretKeyer is implemented by exchange.Exchange's concrete ECDH-1PU
+// type to let a recipient re-derive a Direct Key Agreement CEK from the
+// sender's ephemeral and static public keys.
+type senderSecretKeyer interface {
+	SecretKeyFromSender(senderEphemeralPublicKey, senderStaticPublicKey *ecdsa.PublicKey, theirAgreementInfo []byte) ([]byte, error)
+}
+
+// Decrypter reverses Encrypter: it parses epk/apu/apv from the JWE header,
+// re-derives the KEK/CEK and verifies and decrypts the payload.
+type Decrypter struct {
+	recipientStatic *ecdsa.PrivateKey
+	senderStatic    *ecdsa.PublicKey
+	h               func() hash.Hash
+}
+
+// NewDecrypter returns a Decrypter for tokens sent by senderStaticPublicKey
+// to recipientStaticPrivateKey.
+func NewDecrypter(recipientStaticPrivateKey *ecdsa.PrivateKey, senderStaticPublicKey *ecdsa.PublicKey, h func() hash.Hash) (*Decrypter, error) {
+	if recipientStaticPrivateKey == nil {
+		return nil, fmt.Errorf("recipient static private key is mandatory")
+	}
+	if senderStaticPublicKey == nil {
+		return nil, fmt.Errorf("sender static public key is mandatory")
+	}
+	if h == nil {
+		return nil, fmt.Errorf("hash function is mandatory")
+	}
+
+	return &Decrypter{
+		recipientStatic: recipientStaticPrivateKey,
+		senderStatic:    senderStaticPublicKey,
+		h:               h,
+	}, nil
+}
+
+// Decrypt parses and decrypts a compact ECDH-1PU JWE token, returning the
+// plaintext payload.
+func (d *Decrypter) Decrypt(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("malformed compact jwe: expected 5 parts, got %d", len(parts))
+	}
+	protected, rawEncryptedKey, rawIV, rawCiphertext, rawTag := parts[0], parts[1], parts[2], parts[3], parts[4]
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode protected header: %v", err)
+	}
+
+	var header jweHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("unable to parse protected header: %v", err)
+	}
+
+	keyWrapAlg, err := keyWrapAlgFromHeader(header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	contentAlg := ContentAlgorithm(header.Enc)
+
+	epk, err := decodeECPublicKey(header.EPK, d.recipientStatic.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode epk header: %v", err)
+	}
+
+	apu, err := base64.RawURLEncoding.DecodeString(header.APU)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode apu header: %v", err)
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(header.APV)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode apv header: %v", err)
+	}
+
+	encryptedKey, err := base64.RawURLEncoding.DecodeString(rawEncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode encrypted key: %v", err)
+	}
+	iv, err := base64.RawURLEncoding.DecodeString(rawIV)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode iv: %v", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(rawCiphertext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode ciphertext: %v", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(rawTag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode tag: %v", err)
+	}
+
+	contentKeyLen, err := contentKeySize(contentAlg)
+	if err != nil {
+		return nil, err
+	}
+
+	var cek []byte
+	if keyWrapAlg == Direct {
+		recipient, ok := exchange.ECDH1PU(d.recipientStatic, d.h, []byte(contentAlg), uint32(contentKeyLen*8), apu).(senderSecretKeyer)
+		if !ok {
+			return nil, fmt.Errorf("exchange implementation does not support recipient-side derivation")
+		}
+
+		cek, err = recipient.SecretKeyFromSender(epk, d.senderStatic, apv)
+		if err != nil {
+			return nil, fmt.Errorf("unable to derive content encryption key: %v", err)
+		}
+	} else {
+		wrapKeyLen, err := wrapKeySize(keyWrapAlg)
+		if err != nil {
+			return nil, err
+		}
+
+		// RFC 7518 SS4.6.2: the Concat KDF AlgorithmID is the full "alg"
+		// header value, not the bare wrap name -- unlike Direct mode, which
+		// uses the "enc" value.
+		kw := exchange.ECDH1PUKeyWrap(d.recipientStatic, nil, d.senderStatic, d.h, []byte(algHeader(keyWrapAlg)), uint32(wrapKeyLen*8), apu, apv)
+		cek, err = kw.UnwrapKey(epk, encryptedKey, tag)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unwrap content encryption key: %v", err)
+		}
+	}
+
+	aead, err := newContentAEAD(contentAlg, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := append(append([]byte{}, ciphertext...), tag...)
+	plaintext, err := aead.Open(nil, iv, sealed, []byte(protected))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt payload: %v", err)
+	}
+
+	return plaintext, nil
+}
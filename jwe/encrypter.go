@@ -0,0 +1,139 @@
+package jwe
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"zntr.io/exchange"
+)
+
+// Encrypter produces ECDH-1PU compact JWE tokens from sender/recipient
+// static keys, generating the ephemeral key pair and computing apu/apv/epk
+// and the fixed info itself.
+type Encrypter struct {
+	senderStatic    *ecdsa.PrivateKey
+	recipientStatic *ecdsa.PublicKey
+	h               func() hash.Hash
+	keyWrapAlg      KeyWrapAlgorithm
+	contentAlg      ContentAlgorithm
+	apu, apv        []byte
+}
+
+// NewEncrypter returns an Encrypter producing ECDH-1PU JWE tokens from
+// senderStaticPrivateKey to recipientStaticPublicKey. keyWrapAlg selects
+// Direct Key Agreement (Direct) or Key Agreement with Key Wrapping
+// (A128KW/A192KW/A256KW); contentAlg selects the JWE "enc" algorithm.
+func NewEncrypter(senderStaticPrivateKey *ecdsa.PrivateKey, recipientStaticPublicKey *ecdsa.PublicKey, h func() hash.Hash, keyWrapAlg KeyWrapAlgorithm, contentAlg ContentAlgorithm, apu, apv []byte) (*Encrypter, error) {
+	if senderStaticPrivateKey == nil {
+		return nil, fmt.Errorf("sender static private key is mandatory")
+	}
+	if recipientStaticPublicKey == nil {
+		return nil, fmt.Errorf("recipient static public key is mandatory")
+	}
+	if h == nil {
+		return nil, fmt.Errorf("hash function is mandatory")
+	}
+
+	return &Encrypter{
+		senderStatic:    senderStaticPrivateKey,
+		recipientStatic: recipientStaticPublicKey,
+		h:               h,
+		keyWrapAlg:      keyWrapAlg,
+		contentAlg:      contentAlg,
+		apu:             apu,
+		apv:             apv,
+	}, nil
+}
+
+// Encrypt returns the compact serialization of plaintext encrypted for the
+// recipient configured on e.
+func (e *Encrypter) Encrypt(plaintext []byte) (string, error) {
+	ephemeral, err := ecdsa.GenerateKey(e.senderStatic.Curve, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate ephemeral key: %v", err)
+	}
+
+	epk, err := encodeECPublicKey(&ephemeral.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode ephemeral public key: %v", err)
+	}
+
+	header := jweHeader{
+		Alg: algHeader(e.keyWrapAlg),
+		Enc: string(e.contentAlg),
+		EPK: epk,
+		APU: base64.RawURLEncoding.EncodeToString(e.apu),
+		APV: base64.RawURLEncoding.EncodeToString(e.apv),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode protected header: %v", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+	aad := []byte(protected)
+
+	contentKeyLen, err := contentKeySize(e.contentAlg)
+	if err != nil {
+		return "", err
+	}
+
+	var cek []byte
+	if e.keyWrapAlg == Direct {
+		cek, err = exchange.ECDH1PU(e.senderStatic, e.h, []byte(e.contentAlg), uint32(contentKeyLen*8), e.apu).SecretKey(ephemeral, e.recipientStatic, e.apv)
+		if err != nil {
+			return "", fmt.Errorf("unable to derive content encryption key: %v", err)
+		}
+	} else {
+		cek = make([]byte, contentKeyLen)
+		if _, err := rand.Read(cek); err != nil {
+			return "", fmt.Errorf("unable to generate content encryption key: %v", err)
+		}
+	}
+
+	aead, err := newContentAEAD(e.contentAlg, cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("unable to generate iv: %v", err)
+	}
+
+	tagSize, err := contentTagSize(e.contentAlg)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, tag := splitSealed(aead.Seal(nil, iv, plaintext, aad), tagSize)
+
+	var encryptedKey []byte
+	if e.keyWrapAlg != Direct {
+		wrapKeyLen, err := wrapKeySize(e.keyWrapAlg)
+		if err != nil {
+			return "", err
+		}
+
+		// RFC 7518 SS4.6.2: the Concat KDF AlgorithmID is the full "alg"
+		// header value, not the bare wrap name -- unlike Direct mode, which
+		// uses the "enc" value.
+		kw := exchange.ECDH1PUKeyWrap(e.senderStatic, ephemeral, e.recipientStatic, e.h, []byte(algHeader(e.keyWrapAlg)), uint32(wrapKeyLen*8), e.apu, e.apv)
+		encryptedKey, _, err = kw.WrapKey(cek, tag)
+		if err != nil {
+			return "", fmt.Errorf("unable to wrap content encryption key: %v", err)
+		}
+	}
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
@@ -0,0 +1,217 @@
+// Package jwe provides a compact ECDH-1PU JWE Encrypter/Decrypter facade so
+// callers don't have to hand-assemble headers, derive the CEK and inject
+// apu/apv/epk themselves.
+package jwe
+
+import (
+	"crypto/aes"
+	stdcipher "crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"zntr.io/exchange/internal/cipher"
+)
+
+// ContentAlgorithm identifies a supported JWE "enc" content encryption
+// algorithm.
+type ContentAlgorithm string
+
+// Supported content encryption algorithms.
+const (
+	A128GCM      ContentAlgorithm = "A128GCM"
+	A192GCM      ContentAlgorithm = "A192GCM"
+	A256GCM      ContentAlgorithm = "A256GCM"
+	A128CBCHS256 ContentAlgorithm = "A128CBC-HS256"
+	A192CBCHS384 ContentAlgorithm = "A192CBC-HS384"
+	A256CBCHS512 ContentAlgorithm = "A256CBC-HS512"
+)
+
+// KeyWrapAlgorithm identifies a supported JWE "alg" key management
+// algorithm layered on top of ECDH-1PU. Direct selects ECDH-1PU Direct Key
+// Agreement, where the KDF output is used as the CEK itself.
+type KeyWrapAlgorithm string
+
+// Supported key management algorithms.
+const (
+	Direct KeyWrapAlgorithm = ""
+	A128KW KeyWrapAlgorithm = "A128KW"
+	A192KW KeyWrapAlgorithm = "A192KW"
+	A256KW KeyWrapAlgorithm = "A256KW"
+)
+
+// jweHeader is the JWE Protected Header for a ECDH-1PU compact token.
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	EPK *ecJWK `json:"epk"`
+	APU string `json:"apu,omitempty"`
+	APV string `json:"apv,omitempty"`
+}
+
+// ecJWK is the minimal EC public key JWK representation needed to carry an
+// ephemeral public key in a JWE header.
+type ecJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func algHeader(keyWrapAlg KeyWrapAlgorithm) string {
+	if keyWrapAlg == Direct {
+		return "ECDH-1PU"
+	}
+
+	return "ECDH-1PU+" + string(keyWrapAlg)
+}
+
+func keyWrapAlgFromHeader(alg string) (KeyWrapAlgorithm, error) {
+	if alg == "ECDH-1PU" {
+		return Direct, nil
+	}
+
+	wrapAlg, ok := strings.CutPrefix(alg, "ECDH-1PU+")
+	if !ok {
+		return "", fmt.Errorf("unsupported alg header %q", alg)
+	}
+
+	return KeyWrapAlgorithm(wrapAlg), nil
+}
+
+// contentKeySize returns the CEK size in bytes for a content encryption
+// algorithm.
+func contentKeySize(alg ContentAlgorithm) (int, error) {
+	switch alg {
+	case A128GCM:
+		return 16, nil
+	case A192GCM:
+		return 24, nil
+	case A256GCM:
+		return 32, nil
+	case A128CBCHS256:
+		return 32, nil
+	case A192CBCHS384:
+		return 48, nil
+	case A256CBCHS512:
+		return 64, nil
+	default:
+		return 0, fmt.Errorf("unsupported content encryption algorithm %q", alg)
+	}
+}
+
+// contentTagSize returns the authentication tag size in bytes appended by
+// newContentAEAD for a content encryption algorithm.
+func contentTagSize(alg ContentAlgorithm) (int, error) {
+	switch alg {
+	case A128GCM, A192GCM, A256GCM, A128CBCHS256:
+		return 16, nil
+	case A192CBCHS384:
+		return 24, nil
+	case A256CBCHS512:
+		return 32, nil
+	default:
+		return 0, fmt.Errorf("unsupported content encryption algorithm %q", alg)
+	}
+}
+
+// wrapKeySize returns the KEK size in bytes for a key wrap algorithm.
+func wrapKeySize(alg KeyWrapAlgorithm) (int, error) {
+	switch alg {
+	case A128KW:
+		return 16, nil
+	case A192KW:
+		return 24, nil
+	case A256KW:
+		return 32, nil
+	default:
+		return 0, fmt.Errorf("unsupported key wrap algorithm %q", alg)
+	}
+}
+
+// newContentAEAD returns the AEAD used to encrypt/decrypt the JWE payload
+// for the given content encryption algorithm and CEK.
+func newContentAEAD(alg ContentAlgorithm, key []byte) (stdcipher.AEAD, error) {
+	switch alg {
+	case A128GCM, A192GCM, A256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize aes cipher: %v", err)
+		}
+
+		return stdcipher.NewGCM(block)
+	case A128CBCHS256:
+		return cipher.NewA128CBCHS256(key)
+	case A192CBCHS384:
+		return cipher.NewA192CBCHS384(key)
+	case A256CBCHS512:
+		return cipher.NewA256CBCHS512(key)
+	default:
+		return nil, fmt.Errorf("unsupported content encryption algorithm %q", alg)
+	}
+}
+
+func encodeECPublicKey(pub *ecdsa.PublicKey) (*ecJWK, error) {
+	crv, err := curveName(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (pub.Curve.Params().BitSize + 7) / 8
+
+	return &ecJWK{
+		Kty: "EC",
+		Crv: crv,
+		X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+	}, nil
+}
+
+func decodeECPublicKey(jwk *ecJWK, curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	if jwk == nil {
+		return nil, fmt.Errorf("missing epk header")
+	}
+
+	wantCrv, err := curveName(curve)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Crv != wantCrv {
+		return nil, fmt.Errorf("unexpected epk curve %q, want %q", jwk.Crv, wantCrv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode epk.x: %v", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode epk.y: %v", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+func curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	default:
+		return "", fmt.Errorf("unsupported curve %q", curve.Params().Name)
+	}
+}
+
+func splitSealed(sealed []byte, tagSize int) (ciphertext, tag []byte) {
+	return sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+}
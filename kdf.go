@@ -0,0 +1,142 @@
+package exchange
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// KDF abstracts the key derivation function ECDH1PU and ECDH1PUKeyWrap run
+// over the agreed shared secret z and algorithm-specific fixed info to
+// produce dkLen bytes of keying material.
+type KDF interface {
+	Derive(z, fixedInfo []byte, dkLen int) ([]byte, error)
+}
+
+// NISTConcatKDF implements the SP 800-56A Concatenation Key Derivation
+// Function (One-Step), using Hash as the underlying hash function. It is
+// the default KDF used by ECDH1PU and ECDH1PUKeyWrap.
+type NISTConcatKDF struct {
+	Hash func() hash.Hash
+}
+
+// Derive implements KDF.
+func (k NISTConcatKDF) Derive(z, fixedInfo []byte, dkLen int) ([]byte, error) {
+	if k.Hash == nil {
+		return nil, fmt.Errorf("hash function is mandatory")
+	}
+
+	return nistKdf(k.Hash(), z, fixedInfo, uint32(dkLen))
+}
+
+// HKDF implements RFC 5869 HKDF-Extract-and-Expand, using Hash as the
+// underlying hash function and Salt as the HKDF-Extract salt. fixedInfo is
+// passed through as the HKDF "info" parameter.
+type HKDF struct {
+	Hash func() hash.Hash
+	Salt []byte
+}
+
+// Derive implements KDF.
+func (k HKDF) Derive(z, fixedInfo []byte, dkLen int) ([]byte, error) {
+	if k.Hash == nil {
+		return nil, fmt.Errorf("hash function is mandatory")
+	}
+
+	dk := make([]byte, dkLen)
+	if _, err := io.ReadFull(hkdf.New(k.Hash, z, k.Salt, fixedInfo), dk); err != nil {
+		return nil, fmt.Errorf("unable to read hkdf output: %v", err)
+	}
+
+	return dk, nil
+}
+
+// KMAC implements a single-step KDF using KMAC (NIST SP 800-185) as the
+// PRF, keyed by z, over fixedInfo. It targets deployments that standardize
+// on SHA-3/cSHAKE primitives for algorithm agility rather than SHA-2. XOF
+// constructs the underlying cSHAKE extendable output function --
+// sha3.NewCShake128 for KMAC128, sha3.NewCShake256 for KMAC256 -- and Rate
+// must be set to the matching cSHAKE block size in bytes (168 for
+// cSHAKE128, 136 for cSHAKE256), which KMAC's byte-padding needs and
+// sha3.ShakeHash has no way to report.
+type KMAC struct {
+	XOF  func(N, S []byte) sha3.ShakeHash
+	Rate int
+}
+
+// Derive implements KDF.
+func (k KMAC) Derive(z, fixedInfo []byte, dkLen int) ([]byte, error) {
+	if k.XOF == nil {
+		return nil, fmt.Errorf("xof is mandatory")
+	}
+	if k.Rate <= 0 {
+		return nil, fmt.Errorf("rate is mandatory")
+	}
+
+	// newX = bytepad(encode_string(K), rate) || X || right_encode(L)
+	newX := bytepad(encodeString(z), k.Rate)
+	newX = append(newX, fixedInfo...)
+	newX = append(newX, rightEncode(uint64(dkLen)*8)...)
+
+	xof := k.XOF([]byte("KMAC"), nil)
+	if _, err := xof.Write(newX); err != nil {
+		return nil, fmt.Errorf("unable to write kmac input: %v", err)
+	}
+
+	dk := make([]byte, dkLen)
+	if _, err := io.ReadFull(xof, dk); err != nil {
+		return nil, fmt.Errorf("unable to read kmac output: %v", err)
+	}
+
+	return dk, nil
+}
+
+// ----------------------------------------------------------------
+
+// leftEncode implements SP 800-185's left_encode: the big-endian encoding
+// of x, prefixed with a single byte giving its own length.
+func leftEncode(x uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, x)
+
+	i := 0
+	for i < 7 && b[i] == 0 {
+		i++
+	}
+
+	return append([]byte{byte(8 - i)}, b[i:]...)
+}
+
+// rightEncode is left_encode with the length byte moved to the end.
+func rightEncode(x uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, x)
+
+	i := 0
+	for i < 7 && b[i] == 0 {
+		i++
+	}
+
+	return append(b[i:], byte(8-i))
+}
+
+// encodeString implements SP 800-185's encode_string: left_encode(len(s) in
+// bits) || s.
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
+
+// bytepad implements SP 800-185's bytepad: prepend left_encode(rate) to x
+// and pad with zero bytes out to a multiple of rate.
+func bytepad(x []byte, rate int) []byte {
+	padded := append(leftEncode(uint64(rate)), x...)
+	if rem := len(padded) % rate; rem != 0 {
+		padded = append(padded, make([]byte, rate-rem)...)
+	}
+
+	return padded
+}
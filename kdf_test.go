@@ -0,0 +1,150 @@
+package exchange
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func mustHexDecode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// Values from RFC 5869 Appendix A.1, "Test Case 1" (Basic test case with
+// SHA-256).
+func TestHKDF_RFC5869TestCase1(t *testing.T) {
+	ikm := mustHexDecode("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt := mustHexDecode("000102030405060708090a0b0c")
+	info := mustHexDecode("f0f1f2f3f4f5f6f7f8f9")
+	want := mustHexDecode("3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+
+	kdf := HKDF{Hash: sha256.New, Salt: salt}
+	got, err := kdf.Derive(ikm, info, len(want))
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Derive() = %x, want %x", got, want)
+	}
+}
+
+func TestHKDF_Derive_MissingHash(t *testing.T) {
+	kdf := HKDF{}
+	if _, err := kdf.Derive([]byte("z"), []byte("info"), 32); err == nil {
+		t.Error("Derive() expected error, got nil")
+	}
+}
+
+func TestNISTConcatKDF_Derive(t *testing.T) {
+	z := []byte("shared-secret")
+	info := []byte("fixed-info")
+
+	kdf := NISTConcatKDF{Hash: sha256.New}
+	dk1, err := kdf.Derive(z, info, 32)
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if len(dk1) != 32 {
+		t.Fatalf("Derive() returned %d bytes, want 32", len(dk1))
+	}
+
+	dk2, err := kdf.Derive(z, info, 32)
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if !bytes.Equal(dk1, dk2) {
+		t.Error("Derive() is not deterministic for identical inputs")
+	}
+
+	dk3, err := kdf.Derive(z, []byte("other-info"), 32)
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if bytes.Equal(dk1, dk3) {
+		t.Error("Derive() produced identical output for different fixedInfo")
+	}
+}
+
+func TestNISTConcatKDF_Derive_MissingHash(t *testing.T) {
+	kdf := NISTConcatKDF{}
+	if _, err := kdf.Derive([]byte("z"), []byte("info"), 32); err == nil {
+		t.Error("Derive() expected error, got nil")
+	}
+}
+
+// Values from NIST SP 800-185's KMAC examples, "KMAC128 Sample #1"
+// (Key length 256 bits, S = "", L = 256 bits).
+func TestKMAC_Derive_SP800185Sample1(t *testing.T) {
+	key := mustHexDecode("404142434445464748494A4B4C4D4E4F505152535455565758595A5B5C5D5E5F")
+	x := mustHexDecode("00010203")
+	want := mustHexDecode("E5780B0D3EA6F7D3A429C5706AA43A00FADBD7D49628839E3187243F456EE14E")
+
+	kdf := KMAC{XOF: sha3.NewCShake128, Rate: 168}
+	got, err := kdf.Derive(key, x, len(want))
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Derive() = %x, want %x", got, want)
+	}
+}
+
+func TestKMAC_Derive(t *testing.T) {
+	tests := []struct {
+		name string
+		xof  func(N, S []byte) sha3.ShakeHash
+		rate int
+	}{
+		{name: "KMAC128", xof: sha3.NewCShake128, rate: 168},
+		{name: "KMAC256", xof: sha3.NewCShake256, rate: 136},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := []byte("shared-secret")
+			info := []byte("fixed-info")
+
+			kdf := KMAC{XOF: tt.xof, Rate: tt.rate}
+			dk1, err := kdf.Derive(z, info, 32)
+			if err != nil {
+				t.Fatalf("Derive() error = %v", err)
+			}
+			if len(dk1) != 32 {
+				t.Fatalf("Derive() returned %d bytes, want 32", len(dk1))
+			}
+
+			dk2, err := kdf.Derive(z, info, 32)
+			if err != nil {
+				t.Fatalf("Derive() error = %v", err)
+			}
+			if !bytes.Equal(dk1, dk2) {
+				t.Error("Derive() is not deterministic for identical inputs")
+			}
+
+			dk3, err := kdf.Derive([]byte("other-secret"), info, 32)
+			if err != nil {
+				t.Fatalf("Derive() error = %v", err)
+			}
+			if bytes.Equal(dk1, dk3) {
+				t.Error("Derive() produced identical output for different z")
+			}
+		})
+	}
+}
+
+func TestKMAC_Derive_MissingXOFOrRate(t *testing.T) {
+	if _, err := (KMAC{Rate: 136}).Derive([]byte("z"), []byte("info"), 32); err == nil {
+		t.Error("Derive() expected error for missing xof, got nil")
+	}
+	if _, err := (KMAC{XOF: sha3.NewCShake256}).Derive([]byte("z"), []byte("info"), 32); err == nil {
+		t.Error("Derive() expected error for missing rate, got nil")
+	}
+}